@@ -0,0 +1,130 @@
+package httplog
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// accessLogOutput returns the writer "clf"/"combined" access logs are
+// written to: Options.Output if configured, otherwise stderr to match
+// hclog's own default.
+func accessLogOutput() io.Writer {
+	if DefaultOptions.Output != nil {
+		return DefaultOptions.Output
+	}
+	return os.Stderr
+}
+
+// CommonLoggingHandler returns middleware that writes one Apache Common Log
+// Format line per request directly to out, bypassing the hclog structured
+// formatter entirely.
+func CommonLoggingHandler(out io.Writer) func(http.Handler) http.Handler {
+	return accessLogHandler(out, false)
+}
+
+// CombinedLoggingHandler returns middleware that writes one NCSA Combined
+// Log Format line per request (Common Log Format plus referer and
+// user-agent) directly to out, so request logs can be piped into
+// log-analysis tools such as GoAccess or AWStats that expect this format.
+func CombinedLoggingHandler(out io.Writer) func(http.Handler) http.Handler {
+	return accessLogHandler(out, true)
+}
+
+func accessLogHandler(out io.Writer, combined bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			t1 := time.Now()
+			next.ServeHTTP(ww, r)
+
+			writeAccessLogLine(out, r, ww.Status(), ww.BytesWritten(), t1, combined)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+func writeAccessLogLine(out io.Writer, r *http.Request, status, bytes int, start time.Time, combined bool) {
+	user := "-"
+	if name, _, ok := r.BasicAuth(); ok && name != "" {
+		user = name
+	}
+
+	line := fmt.Sprintf("%s - %s [%s] %s %d %d",
+		remoteIP(r),
+		user,
+		start.Format("02/Jan/2006:15:04:05 -0700"),
+		clfQuote(fmt.Sprintf("%s %s %s", r.Method, r.RequestURI, r.Proto)),
+		status,
+		bytes,
+	)
+
+	if combined {
+		line += fmt.Sprintf(" %s %s", clfQuote(r.Referer()), clfQuote(r.UserAgent()))
+	}
+
+	fmt.Fprintln(out, line)
+}
+
+// clfQuote wraps s in double quotes, escaping it per the Common/Combined
+// Log Format convention (as used by Apache/NCSA): backslashes and double
+// quotes are backslash-escaped, and other control bytes are rendered as
+// "\xHH". Unlike Go's %q, it never produces Go-style \u/\U escapes.
+func clfQuote(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('"')
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&b, "\\x%02x", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	b.WriteByte('"')
+	return b.String()
+}
+
+// remoteIP returns the originating client address for r. When
+// Options.TrustedProxies lists r's immediate peer, the left-most address in
+// X-Forwarded-For is used instead so access logs record the real client
+// behind a load balancer or reverse proxy.
+func remoteIP(r *http.Request) string {
+	host := r.RemoteAddr
+	if idx := strings.LastIndex(host, ":"); idx != -1 {
+		host = host[:idx]
+	}
+
+	if len(DefaultOptions.TrustedProxies) == 0 || !isTrustedProxy(host) {
+		return host
+	}
+
+	xff := r.Header.Get("X-Forwarded-For")
+	if xff == "" {
+		return host
+	}
+
+	return strings.TrimSpace(strings.Split(xff, ",")[0])
+}
+
+func isTrustedProxy(host string) bool {
+	for _, p := range DefaultOptions.TrustedProxies {
+		if p == host {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package httplog
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClfQuote(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", `Mozilla/5.0`, `"Mozilla/5.0"`},
+		{"embedded quote", `Evil"Agent`, `"Evil\"Agent"`},
+		{"embedded backslash", `back\slash`, `"back\\slash"`},
+		{"control byte", "tab\tnewline\n", `"tab\x09newline\x0a"`},
+		{"empty", "", `""`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := clfQuote(tc.in); got != tc.want {
+				t.Errorf("clfQuote(%q) = %q, want %q", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWriteAccessLogLineCombinedQuotesRefererAndUserAgent(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/path?x=1", nil)
+	r.Header.Set("Referer", `https://example.com/"injected`)
+	r.Header.Set("User-Agent", `curl\7.0`)
+
+	var buf bytes.Buffer
+	writeAccessLogLine(&buf, r, 200, 42, time.Now(), true)
+
+	line := buf.String()
+	if !strings.Contains(line, `"https://example.com/\"injected"`) {
+		t.Errorf("referer not CLF-escaped in line: %q", line)
+	}
+	if !strings.Contains(line, `"curl\\7.0"`) {
+		t.Errorf("user-agent not CLF-escaped in line: %q", line)
+	}
+}
+
+func TestWriteAccessLogLineUsesBasicAuthUser(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/path", nil)
+	r.SetBasicAuth("alice", "s3cret")
+
+	var buf bytes.Buffer
+	writeAccessLogLine(&buf, r, 200, 0, time.Now(), false)
+
+	if !strings.Contains(buf.String(), " - alice [") {
+		t.Errorf("expected BasicAuth user %q in line: %q", "alice", buf.String())
+	}
+}
+
+func TestRemoteIPIgnoresForwardedForByDefault(t *testing.T) {
+	t.Cleanup(func() { DefaultOptions.TrustedProxies = nil })
+	DefaultOptions.TrustedProxies = nil
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.1:5555"
+	r.Header.Set("X-Forwarded-For", "203.0.113.1, 10.0.0.2")
+
+	if got := remoteIP(r); got != "10.0.0.1" {
+		t.Errorf("remoteIP() = %q, want %q", got, "10.0.0.1")
+	}
+}
+
+func TestRemoteIPUsesForwardedForWhenProxyTrusted(t *testing.T) {
+	t.Cleanup(func() { DefaultOptions.TrustedProxies = nil })
+	DefaultOptions.TrustedProxies = []string{"10.0.0.1"}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.1:5555"
+	r.Header.Set("X-Forwarded-For", "203.0.113.1, 10.0.0.2")
+
+	if got := remoteIP(r); got != "203.0.113.1" {
+		t.Errorf("remoteIP() = %q, want %q", got, "203.0.113.1")
+	}
+}
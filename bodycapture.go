@@ -0,0 +1,171 @@
+package httplog
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// requestResponseBody carries the captured request/response bodies (and
+// their content types, needed to redact them) from Handler through to the
+// active Formatter's Entry.Write.
+type requestResponseBody struct {
+	requestBody         []byte
+	requestContentType  string
+	responseBody        []byte
+	responseContentType string
+}
+
+// defaultMaxBodyBytes is used when Options.MaxBodyBytes is unset.
+const defaultMaxBodyBytes = 64 * 1024
+
+func maxBodyBytes() int64 {
+	if DefaultOptions.MaxBodyBytes > 0 {
+		return DefaultOptions.MaxBodyBytes
+	}
+	return defaultMaxBodyBytes
+}
+
+// captureContentType reports whether contentType is one this package knows
+// how to capture and redact: application/json,
+// application/x-www-form-urlencoded, or any text/* type.
+func captureContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+	switch {
+	case mediaType == "application/json":
+		return true
+	case mediaType == "application/x-www-form-urlencoded":
+		return true
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	default:
+		return false
+	}
+}
+
+// teeRequestBody wraps r.Body so up to maxBodyBytes() bytes are copied into
+// the returned buffer as the handler chain reads the body, without
+// otherwise changing what downstream handlers see.
+func teeRequestBody(r *http.Request) io.ReadWriter {
+	buf := newLimitBuffer(int(maxBodyBytes()))
+	if r.Body != nil {
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{
+			Reader: io.TeeReader(r.Body, buf),
+			Closer: r.Body,
+		}
+	}
+	return buf
+}
+
+// redactBody formats a captured request/response body for the log,
+// redacting Options.RedactJSONFields from JSON and form bodies. Any other
+// content type is returned as-is.
+func redactBody(contentType string, body []byte) string {
+	if len(DefaultOptions.RedactJSONFields) == 0 || len(body) == 0 {
+		return string(body)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+
+	switch strings.ToLower(strings.TrimSpace(mediaType)) {
+	case "application/json":
+		return redactJSON(body)
+	case "application/x-www-form-urlencoded":
+		return redactForm(body)
+	default:
+		return string(body)
+	}
+}
+
+func redactJSON(body []byte) string {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return string(body)
+	}
+
+	redactJSONValue(v, nil)
+
+	out, err := json.Marshal(v)
+	if err != nil {
+		return string(body)
+	}
+	return string(out)
+}
+
+// redactJSONValue walks v in place, replacing any object value whose key
+// matches Options.RedactJSONFields with "***". A configured field with no
+// dot (e.g. "password") matches a leaf key at any depth; a dotted field
+// (e.g. "user.password") only matches that exact path.
+func redactJSONValue(v interface{}, path []string) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			childPath := append(append([]string{}, path...), strings.ToLower(k))
+			if matchesRedactField(childPath) {
+				val[k] = "***"
+				continue
+			}
+			redactJSONValue(child, childPath)
+		}
+	case []interface{}:
+		for _, child := range val {
+			redactJSONValue(child, path)
+		}
+	}
+}
+
+func matchesRedactField(path []string) bool {
+	joined := strings.Join(path, ".")
+	leaf := path[len(path)-1]
+	for _, field := range DefaultOptions.RedactJSONFields {
+		if strings.Contains(field, ".") {
+			if strings.EqualFold(joined, field) {
+				return true
+			}
+			continue
+		}
+		if strings.EqualFold(leaf, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactForm replaces the value of any matching "key=value" pair with the
+// literal "***", leaving every other pair's original encoding and the
+// overall field order untouched -- unlike url.Values.Encode, which would
+// percent-encode and alphabetically reorder the whole body.
+func redactForm(body []byte) string {
+	pairs := strings.Split(string(body), "&")
+	for i, pair := range pairs {
+		key, _, hasValue := strings.Cut(pair, "=")
+		if !hasValue {
+			continue
+		}
+
+		decodedKey, err := url.QueryUnescape(key)
+		if err != nil {
+			decodedKey = key
+		}
+
+		if matchesRedactField([]string{decodedKey}) {
+			pairs[i] = key + "=***"
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
@@ -0,0 +1,36 @@
+package httplog
+
+import "testing"
+
+func TestRedactBodyRedactsNestedJSONFieldByLeafName(t *testing.T) {
+	t.Cleanup(func() { DefaultOptions.RedactJSONFields = nil })
+	DefaultOptions.RedactJSONFields = []string{"password"}
+
+	got := redactBody("application/json", []byte(`{"user":{"password":"secret","name":"bob"}}`))
+	want := `{"user":{"name":"bob","password":"***"}}`
+	if got != want {
+		t.Errorf("redactBody() = %q, want %q", got, want)
+	}
+}
+
+func TestRedactBodyDottedJSONFieldMatchesOnlyThatPath(t *testing.T) {
+	t.Cleanup(func() { DefaultOptions.RedactJSONFields = nil })
+	DefaultOptions.RedactJSONFields = []string{"user.password"}
+
+	got := redactBody("application/json", []byte(`{"user":{"password":"secret"},"password":"keep"}`))
+	want := `{"password":"keep","user":{"password":"***"}}`
+	if got != want {
+		t.Errorf("redactBody() = %q, want %q", got, want)
+	}
+}
+
+func TestRedactBodyFormPreservesEncodingAndOrder(t *testing.T) {
+	t.Cleanup(func() { DefaultOptions.RedactJSONFields = nil })
+	DefaultOptions.RedactJSONFields = []string{"Token"}
+
+	got := redactBody("application/x-www-form-urlencoded", []byte("Token=abc&keep=1"))
+	want := "Token=***&keep=1"
+	if got != want {
+		t.Errorf("redactBody() = %q, want %q", got, want)
+	}
+}
@@ -1,6 +1,8 @@
 package httplog
 
 import (
+	"io"
+	"net/http"
 	"strings"
 	"time"
 
@@ -8,13 +10,14 @@ import (
 )
 
 var DefaultOptions = Options{
-	Name:        "",
-	Level:       "info",
-	JSONFormat:  false,
-	TimeFormat:  time.RFC3339Nano,
-	Concise:     false,
-	Tags:        nil,
-	SkipHeaders: nil,
+	Name:            "",
+	Level:           "info",
+	JSONFormat:      false,
+	TimeFormat:      time.RFC3339Nano,
+	Concise:         false,
+	Tags:            nil,
+	SkipHeaders:     nil,
+	AccessLogFormat: "hclog",
 }
 
 type Options struct {
@@ -43,6 +46,62 @@ type Options struct {
 
 	// SkipHeaders are additional headers which are redacted from the logs
 	SkipHeaders []string
+
+	// SampleRate, if set (0, 1], logs only that fraction of 2xx/3xx
+	// requests; 4xx/5xx responses are always logged in full. Ignored if
+	// SampleFunc or SamplePerSecond is set.
+	SampleRate float64
+
+	// SamplePerSecond, if set, caps 2xx/3xx logging to that many requests
+	// per second via a token bucket, bounding bursts instead of hard
+	// dropping once the rate is exceeded. 4xx/5xx responses are unaffected.
+	// Takes precedence over SampleRate, but not over SampleFunc.
+	SamplePerSecond int
+
+	// SampleFunc, if set, is consulted for every 2xx/3xx response on a path
+	// without a SkipPath/SamplePath override and receives the request and
+	// its final status code; it takes precedence over SampleRate and
+	// SamplePerSecond. 4xx/5xx responses are unaffected.
+	SampleFunc func(r *http.Request, status int) bool
+
+	// AccessLogFormat selects the output emitted by RequestLogger/Handler:
+	// "hclog" (default) keeps the structured hclog output, "json" is the
+	// same but forces JSONFormat on, and "clf"/"combined" switch to an
+	// Apache Common/NCSA Combined Log Format line instead, for piping into
+	// tools like GoAccess or AWStats.
+	AccessLogFormat string
+
+	// TrustedProxies lists the remote addresses (without port) allowed to
+	// set X-Forwarded-For; when the immediate peer is in this list, the
+	// left-most X-Forwarded-For address is used as the client IP in "clf"/
+	// "combined" access logs instead of the connection's RemoteAddr.
+	TrustedProxies []string
+
+	// Output, if set, is where both the hclog and "clf"/"combined" access
+	// log output is written, instead of hclog's stderr default. Point this
+	// at an httplog/sink.AsyncSink or sink.RotatingFile to keep request
+	// logging off the hot path and durable across rotation.
+	Output io.Writer
+
+	// CaptureRequestBody logs the request body, for requests whose
+	// Content-Type is application/json, application/x-www-form-urlencoded
+	// or text/*, up to MaxBodyBytes.
+	CaptureRequestBody bool
+
+	// CaptureResponseBody logs the response body for all matching content
+	// types, not just 4xx/5xx responses.
+	CaptureResponseBody bool
+
+	// MaxBodyBytes caps how much of a captured request/response body is
+	// retained and logged when CaptureRequestBody or CaptureResponseBody is
+	// set. Defaults to 64KiB. With neither set, 4xx/5xx response bodies are
+	// kept to their first 512 bytes.
+	MaxBodyBytes int64
+
+	// RedactJSONFields lists JSON keys (case-insensitive, dotted paths like
+	// "user.password" supported) and form field names to replace with
+	// "***" in captured bodies before they are logged.
+	RedactJSONFields []string
 }
 
 // Configure will set new global/default options for the httplog and behaviour
@@ -56,6 +115,14 @@ func Configure(opts Options) {
 		opts.TimeFormat = time.RFC3339Nano
 	}
 
+	if opts.AccessLogFormat == "" {
+		opts.AccessLogFormat = "hclog"
+	}
+
+	if opts.AccessLogFormat == "json" {
+		opts.JSONFormat = true
+	}
+
 	// Pre-downcase all SkipHeaders
 	for i, header := range opts.SkipHeaders {
 		opts.SkipHeaders[i] = strings.ToLower(header)
@@ -68,5 +135,6 @@ func Configure(opts Options) {
 		Level:      hclog.LevelFromString(opts.Level),
 		TimeFormat: opts.TimeFormat,
 		JSONFormat: opts.JSONFormat,
+		Output:     opts.Output,
 	}
 }
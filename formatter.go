@@ -0,0 +1,59 @@
+package httplog
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Formatter constructs a per-request Entry. Implementations translate
+// Entry.Write/Entry.Panic calls into a specific logging backend -- hclog,
+// log/slog, zerolog, ... -- so RequestLogger/Handler stay independent of
+// whichever one is installed. See NewHclogFormatter and NewLoggerFromSlog
+// for the built-in implementations.
+type Formatter interface {
+	NewLogEntry(r *http.Request) Entry
+}
+
+// Entry is the request-scoped logger passed through the handler chain by
+// Handler. Its Write/Panic signatures match chi's middleware.LogEntry, so
+// any Entry can be handed to middleware.WithLogEntry and stays compatible
+// with middleware.Recoverer. With lets callers thread extra fields onto the
+// entry's logger without knowing its backend.
+type Entry interface {
+	Write(status, bytes int, header http.Header, elapsed time.Duration, extra interface{})
+	Panic(v interface{}, stack []byte)
+	With(fields ...interface{}) Entry
+}
+
+// TraceCarrier is implemented by Entry values that carry a W3C/B3 trace
+// context (the hclog and slog built-ins, and any custom Formatter that
+// chooses to support it). It lets Handler and TraceID/SpanID read that
+// context without knowing which Formatter produced the entry. Exported so
+// Formatters living outside this package -- e.g. httplog/zerologfmt -- can
+// implement it too.
+type TraceCarrier interface {
+	TraceContext() TraceContext
+}
+
+// LogEntry returns the request-scoped Entry for ctx, or nil if none is set.
+func LogEntry(ctx context.Context) Entry {
+	entry, _ := ctx.Value(middleware.LogEntryCtxKey).(Entry)
+	return entry
+}
+
+// LogEntrySetField sets a single field on the request-scoped entry.
+func LogEntrySetField(ctx context.Context, key, value string) {
+	if entry := LogEntry(ctx); entry != nil {
+		entry.With(key, value)
+	}
+}
+
+// LogEntrySetFields sets additional fields on the request-scoped entry.
+func LogEntrySetFields(ctx context.Context, fields []interface{}) {
+	if entry := LogEntry(ctx); entry != nil {
+		entry.With(fields...)
+	}
+}
@@ -0,0 +1,132 @@
+package httplog
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/hashicorp/go-hclog"
+)
+
+// NewHclogFormatter adapts an hclog.Logger into a Formatter. This is the
+// formatter RequestLogger/Handler have always used, now also reachable
+// explicitly for composing with other Formatters.
+func NewHclogFormatter(logger hclog.Logger) Formatter {
+	return &hclogFormatter{logger}
+}
+
+type hclogFormatter struct {
+	Logger hclog.Logger
+}
+
+func (f *hclogFormatter) NewLogEntry(r *http.Request) Entry {
+	entry := &hclogEntry{}
+	// msg := fmt.Sprintf("Request: %s %s", r.Method, r.URL.Path)
+	// entry.Logger = l.Logger.Info(msg, requestLogFields(r, true))
+
+	entry.Logger = f.Logger.With(requestLogFields(r, true)...)
+	// if !DefaultOptions.Concise {
+	// 	entry.Logger.Info().Fields(requestLogFields(r, DefaultOptions.Concise)).Msgf(msg)
+	// }
+
+	entry.tc = TraceContextFunc(r)
+	entry.Logger = entry.Logger.With("traceID", entry.tc.TraceID, "spanID", entry.tc.SpanID, "traceFlags", entry.tc.TraceFlags)
+	if entry.tc.TraceState != "" {
+		entry.Logger = entry.Logger.With("traceState", entry.tc.TraceState)
+	}
+
+	return entry
+}
+
+// hclogEntry is the hclog-backed Entry implementation.
+type hclogEntry struct {
+	Logger hclog.Logger
+	msg    string
+
+	tc TraceContext
+}
+
+func (l *hclogEntry) TraceContext() TraceContext {
+	return l.tc
+}
+
+func (l *hclogEntry) Write(status, bytes int, header http.Header, elapsed time.Duration, extra interface{}) {
+	msg := fmt.Sprintf("%d %s", status, statusLabel(status))
+	if l.msg != "" {
+		msg = fmt.Sprintf("%s - %s", msg, l.msg)
+	}
+
+	responseLog := []interface{}{
+		"status", status,
+		"bytes", bytes,
+		"elapsed", float64(elapsed.Nanoseconds()) / 1000000.0, // in milliseconds
+	}
+
+	if !DefaultOptions.Concise {
+		// Include response header, as well as the captured request/response
+		// bodies so we may inspect the data sent to and from the client.
+		if body, ok := extra.(requestResponseBody); ok {
+			if len(body.responseBody) > 0 {
+				responseLog = append(responseLog, "responseBody", redactBody(body.responseContentType, body.responseBody))
+			}
+			if len(body.requestBody) > 0 {
+				responseLog = append(responseLog, "requestBody", redactBody(body.requestContentType, body.requestBody))
+			}
+		}
+		if len(header) > 0 {
+			responseLog = append(responseLog, headerLogField(header)...)
+		}
+	}
+
+	l.Logger.Log(hclog.Level(statusLevel(status)), msg, responseLog...)
+
+	// l.Logger.WithLevel(statusLevel(status)).Fields(map[string]interface{}{
+	// 	"httpResponse": responseLog,
+	// }).Msgf(msg)
+}
+
+func (l *hclogEntry) Panic(v interface{}, stack []byte) {
+	stacktrace := "#"
+	if DefaultOptions.JSONFormat {
+		stacktrace = string(stack)
+	}
+
+	l.Logger = l.Logger.With("stacktrace", stacktrace, "panic", fmt.Sprintf("%+v", v))
+
+	l.msg = fmt.Sprintf("%+v", v)
+
+	if !DefaultOptions.JSONFormat {
+		middleware.PrintPrettyStack(v)
+	}
+}
+
+func (l *hclogEntry) With(fields ...interface{}) Entry {
+	l.Logger = l.Logger.With(fields...)
+	return l
+}
+
+func statusLevel(status int) hclog.Level {
+	switch {
+	case status <= 0:
+		return hclog.Warn
+	case status < 400: // for codes in 100s, 200s, 300s
+		return hclog.Info
+	case status >= 400 && status < 500:
+		return hclog.Warn
+	case status >= 500:
+		return hclog.Error
+	default:
+		return hclog.Info
+	}
+}
+
+// HclogLogger returns the hclog.Logger backing ctx's request-scoped entry,
+// or a null logger if the entry isn't hclog-backed.
+func HclogLogger(ctx context.Context) hclog.Logger {
+	if e, ok := LogEntry(ctx).(*hclogEntry); ok {
+		return e.Logger
+	}
+	return hclog.NewNullLogger()
+}
@@ -0,0 +1,106 @@
+package httplog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// NewLoggerFromSlog adapts an slog.Handler into a Formatter, for users who
+// have standardized on log/slog (Go 1.21+) and don't want to install hclog
+// just to use chi request logging.
+func NewLoggerFromSlog(h slog.Handler) Formatter {
+	return &slogFormatter{slog.New(h)}
+}
+
+type slogFormatter struct {
+	Logger *slog.Logger
+}
+
+func (f *slogFormatter) NewLogEntry(r *http.Request) Entry {
+	entry := &slogEntry{}
+	entry.Logger = f.Logger.With(requestLogFields(r, true)...)
+
+	entry.tc = TraceContextFunc(r)
+	entry.Logger = entry.Logger.With("traceID", entry.tc.TraceID, "spanID", entry.tc.SpanID, "traceFlags", entry.tc.TraceFlags)
+	if entry.tc.TraceState != "" {
+		entry.Logger = entry.Logger.With("traceState", entry.tc.TraceState)
+	}
+
+	return entry
+}
+
+// slogEntry is the log/slog-backed Entry implementation.
+type slogEntry struct {
+	Logger *slog.Logger
+	msg    string
+
+	tc TraceContext
+}
+
+func (l *slogEntry) TraceContext() TraceContext {
+	return l.tc
+}
+
+func (l *slogEntry) Write(status, bytes int, header http.Header, elapsed time.Duration, extra interface{}) {
+	msg := fmt.Sprintf("%d %s", status, statusLabel(status))
+	if l.msg != "" {
+		msg = fmt.Sprintf("%s - %s", msg, l.msg)
+	}
+
+	args := []interface{}{
+		"status", status,
+		"bytes", bytes,
+		"elapsed", float64(elapsed.Nanoseconds()) / 1000000.0, // in milliseconds
+	}
+
+	if !DefaultOptions.Concise {
+		if body, ok := extra.(requestResponseBody); ok {
+			if len(body.responseBody) > 0 {
+				args = append(args, "responseBody", redactBody(body.responseContentType, body.responseBody))
+			}
+			if len(body.requestBody) > 0 {
+				args = append(args, "requestBody", redactBody(body.requestContentType, body.requestBody))
+			}
+		}
+		if len(header) > 0 {
+			args = append(args, headerLogField(header)...)
+		}
+	}
+
+	l.Logger.Log(context.Background(), slogLevel(status), msg, args...)
+}
+
+func (l *slogEntry) Panic(v interface{}, stack []byte) {
+	l.Logger = l.Logger.With("panic", fmt.Sprintf("%+v", v), "stacktrace", string(stack))
+	l.msg = fmt.Sprintf("%+v", v)
+}
+
+func (l *slogEntry) With(fields ...interface{}) Entry {
+	l.Logger = l.Logger.With(fields...)
+	return l
+}
+
+func slogLevel(status int) slog.Level {
+	switch {
+	case status <= 0:
+		return slog.LevelWarn
+	case status < 400: // for codes in 100s, 200s, 300s
+		return slog.LevelInfo
+	case status < 500:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+// SlogLogger returns the *slog.Logger backing ctx's request-scoped entry,
+// or slog.Default() if the entry isn't slog-backed.
+func SlogLogger(ctx context.Context) *slog.Logger {
+	if e, ok := LogEntry(ctx).(*slogEntry); ok {
+		return e.Logger
+	}
+	return slog.Default()
+}
@@ -1,7 +1,6 @@
 package httplog
 
 import (
-	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -26,107 +25,77 @@ func NewLogger(opts ...Options) hclog.Logger {
 	}
 }
 
-// RequestLogger is an http middleware to log http requests and responses.
+// RequestLogger is an http middleware to log http requests and responses
+// using f to build the per-request Entry.
 //
 // NOTE: for simplicity, RequestLogger automatically makes use of the chi RequestID and
 // Recoverer middleware.
-func RequestLogger(logger hclog.Logger) func(next http.Handler) http.Handler {
-	return chi.Chain(middleware.RequestID, Handler(logger), middleware.Recoverer).Handler
+func RequestLogger(f Formatter) func(next http.Handler) http.Handler {
+	return chi.Chain(middleware.RequestID, Handler(f), middleware.Recoverer).Handler
 }
 
-func Handler(logger hclog.Logger) func(next http.Handler) http.Handler {
-	var f middleware.LogFormatter = &requestLogger{logger}
+func Handler(f Formatter) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
 			entry := f.NewLogEntry(r)
 			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
 
-			buf := newLimitBuffer(512)
+			if tc, ok := entry.(TraceCarrier); ok {
+				ctx := tc.TraceContext()
+				if ctx.Generated {
+					ww.Header().Set("traceparent", fmt.Sprintf("00-%s-%s-%s", ctx.TraceID, ctx.SpanID, ctx.TraceFlags))
+				}
+				if ctx.TraceState != "" {
+					ww.Header().Set("tracestate", ctx.TraceState)
+				}
+			}
+
+			bufSize := 512
+			if DefaultOptions.CaptureRequestBody || DefaultOptions.CaptureResponseBody {
+				bufSize = int(maxBodyBytes())
+			}
+			buf := newLimitBuffer(bufSize)
 			ww.Tee(buf)
 
+			var reqBuf io.ReadWriter
+			if DefaultOptions.CaptureRequestBody && captureContentType(r.Header.Get("Content-Type")) {
+				reqBuf = teeRequestBody(r)
+			}
+
 			t1 := time.Now()
 			defer func() {
-				var respBody []byte
-				if ww.Status() >= 400 {
-					respBody, _ = io.ReadAll(buf)
+				status := ww.Status()
+				if !shouldLog(r, status) {
+					return
 				}
-				entry.Write(ww.Status(), ww.BytesWritten(), ww.Header(), time.Since(t1), respBody)
-			}()
 
-			next.ServeHTTP(ww, middleware.WithLogEntry(r, entry))
-		}
-		return http.HandlerFunc(fn)
-	}
-}
-
-type requestLogger struct {
-	Logger hclog.Logger
-}
-
-func (l *requestLogger) NewLogEntry(r *http.Request) middleware.LogEntry {
-	entry := &RequestLoggerEntry{}
-	// msg := fmt.Sprintf("Request: %s %s", r.Method, r.URL.Path)
-	// entry.Logger = l.Logger.Info(msg, requestLogFields(r, true))
-
-	entry.Logger = l.Logger.With(requestLogFields(r, true)...)
-	// if !DefaultOptions.Concise {
-	// 	entry.Logger.Info().Fields(requestLogFields(r, DefaultOptions.Concise)).Msgf(msg)
-	// }
-
-	return entry
-}
-
-type RequestLoggerEntry struct {
-	Logger hclog.Logger
-	msg    string
-}
+				if DefaultOptions.AccessLogFormat == "clf" || DefaultOptions.AccessLogFormat == "combined" {
+					writeAccessLogLine(accessLogOutput(), r, status, ww.BytesWritten(), t1, DefaultOptions.AccessLogFormat == "combined")
+					return
+				}
 
-func (l *RequestLoggerEntry) Write(status, bytes int, header http.Header, elapsed time.Duration, extra interface{}) {
-	msg := fmt.Sprintf("%d %s", status, statusLabel(status))
-	if l.msg != "" {
-		msg = fmt.Sprintf("%s - %s", msg, l.msg)
-	}
+				var body requestResponseBody
+				if status >= 400 || (DefaultOptions.CaptureResponseBody && captureContentType(ww.Header().Get("Content-Type"))) {
+					body.responseBody, _ = io.ReadAll(buf)
+					body.responseContentType = ww.Header().Get("Content-Type")
+				}
+				if reqBuf != nil {
+					body.requestBody, _ = io.ReadAll(reqBuf)
+					body.requestContentType = r.Header.Get("Content-Type")
+				}
 
-	responseLog := []interface{}{
-		"status", status,
-		"bytes", bytes,
-		"elapsed", float64(elapsed.Nanoseconds()) / 1000000.0, // in milliseconds
-	}
+				entry.Write(status, ww.BytesWritten(), ww.Header(), time.Since(t1), body)
+			}()
 
-	if !DefaultOptions.Concise {
-		// Include response header, as well for error status codes (>400) we include
-		// the response body so we may inspect the log message sent back to the client.
-		if status >= 400 {
-			body, _ := extra.([]byte)
-			responseLog = append(responseLog, "responseBody", string(body))
-		}
-		if len(header) > 0 {
-			responseLog = append(responseLog, headerLogField(header)...)
+			next.ServeHTTP(ww, middleware.WithLogEntry(r, entry))
 		}
-	}
-
-	l.Logger.Log(hclog.Level(statusLevel(status)), msg, responseLog...)
-
-	// l.Logger.WithLevel(statusLevel(status)).Fields(map[string]interface{}{
-	// 	"httpResponse": responseLog,
-	// }).Msgf(msg)
-}
-
-func (l *RequestLoggerEntry) Panic(v interface{}, stack []byte) {
-	stacktrace := "#"
-	if DefaultOptions.JSONFormat {
-		stacktrace = string(stack)
-	}
-
-	l.Logger = l.Logger.With("stacktrace", stacktrace, "panic", fmt.Sprintf("%+v", v))
-
-	l.msg = fmt.Sprintf("%+v", v)
-
-	if !DefaultOptions.JSONFormat {
-		middleware.PrintPrettyStack(v)
+		return http.HandlerFunc(fn)
 	}
 }
 
+// requestLogFields builds the base set of fields the built-in hclog and slog
+// Formatters log for an incoming request; concise trims it down to what
+// Options.Concise keeps.
 func requestLogFields(r *http.Request, concise bool) []interface{} {
 	scheme := "http"
 	if r.TLS != nil {
@@ -186,21 +155,6 @@ func headerLogField(header http.Header) []interface{} {
 	return headerField
 }
 
-func statusLevel(status int) hclog.Level {
-	switch {
-	case status <= 0:
-		return hclog.Warn
-	case status < 400: // for codes in 100s, 200s, 300s
-		return hclog.Info
-	case status >= 400 && status < 500:
-		return hclog.Warn
-	case status >= 500:
-		return hclog.Error
-	default:
-		return hclog.Info
-	}
-}
-
 func statusLabel(status int) string {
 	switch {
 	case status >= 100 && status < 300:
@@ -215,31 +169,3 @@ func statusLabel(status int) string {
 		return "Unknown"
 	}
 }
-
-// Helper methods used by the application to get the request-scoped
-// logger entry and set additional fields between handlers.
-//
-// This is a useful pattern to use to set state on the entry as it
-// passes through the handler chain, which at any point can be logged
-// with a call to .Print(), .Info(), etc.
-
-func LogEntry(ctx context.Context) hclog.Logger {
-	entry, ok := ctx.Value(middleware.LogEntryCtxKey).(*RequestLoggerEntry)
-	if !ok || entry == nil {
-		return hclog.NewNullLogger()
-	} else {
-		return entry.Logger
-	}
-}
-
-func LogEntrySetField(ctx context.Context, key, value string) {
-	if entry, ok := ctx.Value(middleware.LogEntryCtxKey).(*RequestLoggerEntry); ok {
-		entry.Logger = entry.Logger.With(key, value)
-	}
-}
-
-func LogEntrySetFields(ctx context.Context, fields []interface{}) {
-	if entry, ok := ctx.Value(middleware.LogEntryCtxKey).(*RequestLoggerEntry); ok {
-		entry.Logger = entry.Logger.With(fields...)
-	}
-}
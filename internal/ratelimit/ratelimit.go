@@ -0,0 +1,50 @@
+// Package ratelimit provides a minimal token bucket used internally to
+// bound bursts when sampling logs at a fixed per-second rate.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// TokenBucket is a thread-safe token bucket limiter that refills
+// continuously at a fixed rate, up to a capacity equal to that rate.
+type TokenBucket struct {
+	mu       sync.Mutex
+	capacity float64
+	tokens   float64
+	rate     float64 // tokens added per second
+	last     time.Time
+}
+
+// New creates a TokenBucket that allows up to ratePerSecond events per
+// second, with bursts up to ratePerSecond.
+func New(ratePerSecond int) *TokenBucket {
+	rate := float64(ratePerSecond)
+	return &TokenBucket{
+		capacity: rate,
+		tokens:   rate,
+		rate:     rate,
+		last:     time.Now(),
+	}
+}
+
+// Allow reports whether an event may proceed, consuming a token if so.
+func (b *TokenBucket) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
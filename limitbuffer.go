@@ -0,0 +1,39 @@
+package httplog
+
+import (
+	"bytes"
+	"io"
+)
+
+// limitBuffer is a bytes.Buffer that silently stops accepting writes once it
+// reaches its limit, so tee-ing a request or response body into it can
+// never grow without bound.
+type limitBuffer struct {
+	*bytes.Buffer
+	limit int
+}
+
+// newLimitBuffer returns a limitBuffer that retains at most size bytes
+// written to it.
+func newLimitBuffer(size int) io.ReadWriter {
+	return &limitBuffer{
+		Buffer: bytes.NewBuffer(make([]byte, 0, size)),
+		limit:  size,
+	}
+}
+
+func (b *limitBuffer) Write(p []byte) (n int, err error) {
+	if b.Buffer.Len() >= b.limit {
+		return len(p), nil
+	}
+
+	limit := b.limit - b.Buffer.Len()
+	if limit > len(p) {
+		limit = len(p)
+	}
+	if _, err := b.Buffer.Write(p[:limit]); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
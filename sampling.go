@@ -0,0 +1,94 @@
+package httplog
+
+import (
+	"math/rand"
+	"net/http"
+	"sync"
+
+	"github.com/Vahatra/chi-http-hclog/internal/ratelimit"
+)
+
+var (
+	pathOverridesMu sync.RWMutex
+	pathOverrides   = map[string]float64{}
+
+	perSecondLimiterMu   sync.Mutex
+	perSecondLimiter     *ratelimit.TokenBucket
+	perSecondLimiterRate int
+)
+
+// SkipPath excludes path's 2xx/3xx requests from request logging, regardless
+// of the globally configured SampleRate or SamplePerSecond; 4xx/5xx
+// responses on path are still logged. This is typically used to silence
+// noisy health-check endpoints such as "/healthz".
+func SkipPath(path string) {
+	SamplePath(path, 0)
+}
+
+// SamplePath overrides the sampling rate for path, logging only a rate
+// fraction (0..1) of its 2xx/3xx requests. 4xx/5xx responses on path are
+// still logged in full, the same as everywhere else.
+func SamplePath(path string, rate float64) {
+	pathOverridesMu.Lock()
+	defer pathOverridesMu.Unlock()
+	pathOverrides[path] = rate
+}
+
+// shouldLog reports whether the completed request described by r and status
+// should be emitted. 4xx/5xx responses always log; otherwise path overrides
+// (SkipPath/SamplePath), then Options.SampleFunc, SamplePerSecond and
+// SampleRate are consulted in that order.
+func shouldLog(r *http.Request, status int) bool {
+	if status >= 400 {
+		return true
+	}
+
+	if rate, ok := pathOverride(r.URL.Path); ok {
+		return sampleAllows(rate)
+	}
+
+	if DefaultOptions.SampleFunc != nil {
+		return DefaultOptions.SampleFunc(r, status)
+	}
+
+	if DefaultOptions.SamplePerSecond > 0 {
+		return samplePerSecondLimiter().Allow()
+	}
+
+	if DefaultOptions.SampleRate > 0 {
+		return sampleAllows(DefaultOptions.SampleRate)
+	}
+
+	return true
+}
+
+func pathOverride(path string) (float64, bool) {
+	pathOverridesMu.RLock()
+	defer pathOverridesMu.RUnlock()
+	rate, ok := pathOverrides[path]
+	return rate, ok
+}
+
+func sampleAllows(rate float64) bool {
+	switch {
+	case rate <= 0:
+		return false
+	case rate >= 1:
+		return true
+	default:
+		return rand.Float64() < rate
+	}
+}
+
+// samplePerSecondLimiter returns the shared token bucket for
+// Options.SamplePerSecond, recreating it if the configured rate changes.
+func samplePerSecondLimiter() *ratelimit.TokenBucket {
+	perSecondLimiterMu.Lock()
+	defer perSecondLimiterMu.Unlock()
+
+	if perSecondLimiter == nil || perSecondLimiterRate != DefaultOptions.SamplePerSecond {
+		perSecondLimiter = ratelimit.New(DefaultOptions.SamplePerSecond)
+		perSecondLimiterRate = DefaultOptions.SamplePerSecond
+	}
+	return perSecondLimiter
+}
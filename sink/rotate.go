@@ -0,0 +1,174 @@
+package sink
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RotateOptions configures a RotatingFile.
+type RotateOptions struct {
+	// MaxSizeBytes rotates the file once the next write would exceed this
+	// size. Zero disables size-based rotation.
+	MaxSizeBytes int64
+
+	// MaxAge rotates the file once it has been open longer than MaxAge.
+	// Zero disables time-based rotation.
+	MaxAge time.Duration
+
+	// MaxBackups is the number of rotated segments to retain; older ones
+	// are removed. Zero keeps all of them.
+	MaxBackups int
+
+	// Compress gzips rotated segments.
+	Compress bool
+}
+
+// RotatingFile is an io.WriteCloser that rotates its underlying file by
+// size and/or age, optionally gzipping rotated segments and pruning old
+// ones past MaxBackups.
+type RotatingFile struct {
+	path string
+	opts RotateOptions
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+// NewRotatingFile opens (or creates) path for appending and returns a
+// RotatingFile that rotates it per opts.
+func NewRotatingFile(path string, opts RotateOptions) (*RotatingFile, error) {
+	rf := &RotatingFile{path: path, opts: opts}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+func (rf *RotatingFile) open() error {
+	f, err := os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	rf.file = f
+	rf.size = info.Size()
+	rf.openedAt = time.Now()
+	return nil
+}
+
+// Write implements io.Writer, rotating the file first if p would push it
+// past MaxSizeBytes or the file has been open longer than MaxAge.
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.shouldRotate(len(p)) {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+func (rf *RotatingFile) shouldRotate(next int) bool {
+	if rf.opts.MaxSizeBytes > 0 && rf.size+int64(next) > rf.opts.MaxSizeBytes {
+		return true
+	}
+	if rf.opts.MaxAge > 0 && time.Since(rf.openedAt) > rf.opts.MaxAge {
+		return true
+	}
+	return false
+}
+
+func (rf *RotatingFile) rotate() error {
+	if err := rf.file.Close(); err != nil {
+		return err
+	}
+
+	rotated := fmt.Sprintf("%s.%s", rf.path, time.Now().Format("20060102T150405.000000000"))
+	if err := os.Rename(rf.path, rotated); err != nil {
+		return err
+	}
+
+	if rf.opts.Compress {
+		if err := gzipFile(rotated); err != nil {
+			return err
+		}
+	}
+
+	if err := rf.pruneBackups(); err != nil {
+		return err
+	}
+
+	return rf.open()
+}
+
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	gw := gzip.NewWriter(dst)
+	if _, err := io.Copy(gw, src); err != nil {
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+func (rf *RotatingFile) pruneBackups() error {
+	if rf.opts.MaxBackups <= 0 {
+		return nil
+	}
+
+	matches, err := filepath.Glob(rf.path + ".*")
+	if err != nil {
+		return err
+	}
+	if len(matches) <= rf.opts.MaxBackups {
+		return nil
+	}
+
+	sort.Strings(matches)
+	for _, old := range matches[:len(matches)-rf.opts.MaxBackups] {
+		if err := os.Remove(old); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the underlying file.
+func (rf *RotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	return rf.file.Close()
+}
@@ -0,0 +1,134 @@
+// Package sink provides buffering and file-rotation helpers that can be
+// plugged into Options.Output so request logging is never made synchronous
+// on the request path, and so rotated logs can be kept durable on disk
+// without pulling in a third-party dependency like lumberjack.
+package sink
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// OverflowPolicy controls what an AsyncSink does once its buffer is full.
+type OverflowPolicy int
+
+const (
+	// DropOnFull discards the record and returns immediately once the
+	// buffer is full, trading durability for latency under load.
+	DropOnFull OverflowPolicy = iota
+	// BlockOnFull blocks the caller until space is available, trading
+	// latency for durability.
+	BlockOnFull
+)
+
+// AsyncOptions configures an AsyncSink.
+type AsyncOptions struct {
+	// BufferSize is the number of records the bounded channel can hold
+	// before Policy kicks in. Defaults to 1024.
+	BufferSize int
+
+	// Policy controls behaviour once the buffer is full. Defaults to
+	// DropOnFull.
+	Policy OverflowPolicy
+}
+
+type record struct {
+	data []byte
+	ack  chan struct{}
+}
+
+// AsyncSink buffers writes in a bounded channel drained by a background
+// goroutine, so a slow or blocking inner io.Writer never serializes
+// request handlers on the hot path.
+type AsyncSink struct {
+	inner  io.Writer
+	policy OverflowPolicy
+
+	records   chan record
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewAsyncSink starts a background goroutine draining into inner and
+// returns an io.Writer that buffers writes ahead of it. Call Close (wired
+// into server shutdown) to drain and stop the goroutine.
+func NewAsyncSink(inner io.Writer, opts AsyncOptions) *AsyncSink {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 1024
+	}
+
+	s := &AsyncSink{
+		inner:   inner,
+		policy:  opts.Policy,
+		records: make(chan record, opts.BufferSize),
+	}
+
+	s.wg.Add(1)
+	go s.run()
+
+	return s
+}
+
+func (s *AsyncSink) run() {
+	defer s.wg.Done()
+	for rec := range s.records {
+		if rec.ack != nil {
+			close(rec.ack)
+			continue
+		}
+		_, _ = s.inner.Write(rec.data)
+	}
+}
+
+// Write implements io.Writer. p is copied before being handed to the
+// background goroutine, since callers are free to reuse their buffer.
+func (s *AsyncSink) Write(p []byte) (int, error) {
+	data := make([]byte, len(p))
+	copy(data, p)
+
+	rec := record{data: data}
+	if s.policy == BlockOnFull {
+		s.records <- rec
+	} else {
+		select {
+		case s.records <- rec:
+		default:
+		}
+	}
+
+	return len(p), nil
+}
+
+// Flush blocks until every record buffered before the call has been
+// written to the inner writer, or ctx is done.
+func (s *AsyncSink) Flush(ctx context.Context) error {
+	ack := make(chan struct{})
+	select {
+	case s.records <- record{ack: ack}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-ack:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close drains any buffered records to the inner writer, stops the
+// background goroutine, and closes inner if it implements io.Closer. It is
+// meant to be wired into server shutdown.
+func (s *AsyncSink) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.records)
+	})
+	s.wg.Wait()
+
+	if c, ok := s.inner.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
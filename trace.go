@@ -0,0 +1,138 @@
+package httplog
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// TraceContext is the distributed trace a request belongs to, parsed from
+// an incoming traceparent/tracestate or b3 header, or generated fresh when
+// none is present.
+type TraceContext struct {
+	// TraceID and SpanID are always 32 and 16 characters respectively, the
+	// W3C Trace Context widths, regardless of which header format they were
+	// parsed from. Parsed IDs are checked for width only; their characters
+	// are kept as received and are not checked to be lowercase hex.
+	TraceID    string
+	SpanID     string
+	TraceFlags string
+
+	// TraceState is the raw W3C "tracestate" header value, if any. It is
+	// opaque to this package and is only ever forwarded verbatim.
+	TraceState string
+
+	// Generated reports that no usable header was present on the request
+	// and TraceID/SpanID were freshly generated instead of parsed.
+	Generated bool
+}
+
+// TraceContextFunc extracts the trace context for an incoming request. The
+// default, ParseTraceContext, understands the W3C traceparent/tracestate
+// headers and falls back to the single-header B3 form, generating a fresh
+// trace/span pair when neither is present.
+//
+// Set this to a custom function -- for example one backed by
+// go.opentelemetry.io/otel's trace.SpanFromContext(r.Context()).SpanContext()
+// -- so request logs correlate with a trace that already exists rather than
+// a fabricated one.
+var TraceContextFunc = ParseTraceContext
+
+// ParseTraceContext extracts the trace context from r's "traceparent" and
+// "tracestate" headers (W3C Trace Context), falling back to the
+// single-header "b3" form, which carries no tracestate equivalent.
+func ParseTraceContext(r *http.Request) TraceContext {
+	if tp := r.Header.Get("traceparent"); tp != "" {
+		if id, sid, flags, ok := parseTraceparent(tp); ok {
+			return TraceContext{
+				TraceID:    id,
+				SpanID:     sid,
+				TraceFlags: flags,
+				TraceState: r.Header.Get("tracestate"),
+			}
+		}
+	}
+
+	if b3 := r.Header.Get("b3"); b3 != "" {
+		if id, sid, flags, ok := parseB3(b3); ok {
+			return TraceContext{TraceID: id, SpanID: sid, TraceFlags: flags}
+		}
+	}
+
+	return TraceContext{TraceID: newTraceID(), SpanID: newSpanID(), TraceFlags: "01", Generated: true}
+}
+
+// parseTraceparent parses the W3C traceparent header:
+// "{version}-{traceID}-{spanID}-{traceFlags}".
+func parseTraceparent(header string) (traceID, spanID, traceFlags string, ok bool) {
+	parts := strings.Split(header, "-")
+	if len(parts) != 4 {
+		return "", "", "", false
+	}
+	if len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return "", "", "", false
+	}
+	return parts[1], parts[2], parts[3], true
+}
+
+// parseB3 parses the single-header B3 form:
+// "{traceID}-{spanID}-{samplingState}-{parentSpanID}", where the sampling
+// state and parent span ID are optional. A 64-bit (16 hex char) traceID is
+// left-padded to the 128-bit width a W3C traceparent requires.
+func parseB3(header string) (traceID, spanID, traceFlags string, ok bool) {
+	parts := strings.Split(header, "-")
+	if len(parts) < 2 {
+		return "", "", "", false
+	}
+
+	traceID, spanID = parts[0], parts[1]
+	if len(spanID) != 16 {
+		return "", "", "", false
+	}
+	switch len(traceID) {
+	case 16:
+		traceID = strings.Repeat("0", 16) + traceID
+	case 32:
+		// already full width
+	default:
+		return "", "", "", false
+	}
+
+	traceFlags = "00"
+	if len(parts) >= 3 && (parts[2] == "1" || parts[2] == "d") {
+		traceFlags = "01"
+	}
+	return traceID, spanID, traceFlags, true
+}
+
+func newTraceID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func newSpanID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// TraceID returns the trace ID of the request-scoped logger entry for ctx,
+// or "" if none is set. It mirrors the LogEntry accessor.
+func TraceID(ctx context.Context) string {
+	if tc, ok := LogEntry(ctx).(TraceCarrier); ok {
+		return tc.TraceContext().TraceID
+	}
+	return ""
+}
+
+// SpanID returns the span ID of the request-scoped logger entry for ctx, or
+// "" if none is set. It mirrors the LogEntry accessor.
+func SpanID(ctx context.Context) string {
+	if tc, ok := LogEntry(ctx).(TraceCarrier); ok {
+		return tc.TraceContext().SpanID
+	}
+	return ""
+}
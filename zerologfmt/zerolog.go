@@ -0,0 +1,95 @@
+// Package zerologfmt adapts github.com/rs/zerolog into an httplog.Formatter,
+// for users who have standardized on zerolog instead of hclog or log/slog.
+// It is a separate subpackage so importing httplog does not pull in
+// zerolog unless this adapter is actually used.
+package zerologfmt
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	httplog "github.com/Vahatra/chi-http-hclog"
+	"github.com/rs/zerolog"
+)
+
+// NewFormatter adapts logger into an httplog.Formatter.
+func NewFormatter(logger zerolog.Logger) httplog.Formatter {
+	return &formatter{logger}
+}
+
+type formatter struct {
+	Logger zerolog.Logger
+}
+
+func (f *formatter) NewLogEntry(r *http.Request) httplog.Entry {
+	e := &entry{tc: httplog.TraceContextFunc(r)}
+
+	ctx := f.Logger.With().Fields(requestLogFields(r)).
+		Str("traceID", e.tc.TraceID).
+		Str("spanID", e.tc.SpanID).
+		Str("traceFlags", e.tc.TraceFlags)
+	if e.tc.TraceState != "" {
+		ctx = ctx.Str("traceState", e.tc.TraceState)
+	}
+	e.Logger = ctx.Logger()
+
+	return e
+}
+
+type entry struct {
+	Logger zerolog.Logger
+	msg    string
+	tc     httplog.TraceContext
+}
+
+// TraceContext implements httplog.TraceCarrier, so TraceID/SpanID and the
+// outgoing traceparent/tracestate headers work with this Formatter too.
+func (e *entry) TraceContext() httplog.TraceContext {
+	return e.tc
+}
+
+func (e *entry) Write(status, bytes int, header http.Header, elapsed time.Duration, extra interface{}) {
+	ev := e.Logger.Info()
+	switch {
+	case status >= 500:
+		ev = e.Logger.Error()
+	case status >= 400:
+		ev = e.Logger.Warn()
+	}
+
+	ev = ev.Int("status", status).
+		Int("bytes", bytes).
+		Float64("elapsed", float64(elapsed.Nanoseconds())/1000000.0)
+
+	msg := fmt.Sprintf("%d", status)
+	if e.msg != "" {
+		msg = fmt.Sprintf("%s - %s", msg, e.msg)
+	}
+
+	ev.Msg(msg)
+}
+
+func (e *entry) Panic(v interface{}, stack []byte) {
+	e.Logger = e.Logger.With().Str("panic", fmt.Sprintf("%+v", v)).Str("stacktrace", string(stack)).Logger()
+	e.msg = fmt.Sprintf("%+v", v)
+}
+
+func (e *entry) With(fields ...interface{}) httplog.Entry {
+	ctx := e.Logger.With()
+	for i := 0; i+1 < len(fields); i += 2 {
+		key, _ := fields[i].(string)
+		ctx = ctx.Interface(key, fields[i+1])
+	}
+	e.Logger = ctx.Logger()
+	return e
+}
+
+func requestLogFields(r *http.Request) map[string]interface{} {
+	return map[string]interface{}{
+		"requestMethod": r.Method,
+		"requestPath":   r.URL.Path,
+		"remoteIP":      r.RemoteAddr,
+		"proto":         r.Proto,
+	}
+}